@@ -13,7 +13,7 @@ func Example_section() {
 message=hello
 	`))
 	if config, err := cp.Finish(); err == nil {
-		section := config.Section("section")
+		section, _ := config.Section("section")
 		message := strings.Join(section.GetPropertyValues("message"), " ")
 		fmt.Println(message)
 	}
@@ -79,9 +79,8 @@ message=world
 }
 
 func ExampleDecodeOptionSet_Decode() {
-	rawSection := RawSection{
-		"key": []string{"1"},
-	}
+	rawSection := NewRawSection()
+	rawSection.addProperty("key", "1")
 
 	spec := DecodeOptionSet{
 		"key": &DecodeOption{Kind: UniqueOption,
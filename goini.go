@@ -8,15 +8,24 @@
 //     file.
 //   - All leading and trailing whitespace is stripped from properties
 //     and values.
+//   - The characters ';', '#', '\', '=' and '[' can be escaped with a
+//     leading '\' to use them literally instead of as a comment, escape,
+//     separator or section marker; a trailing '\\' (an escaped
+//     backslash) does not trigger a continuation.
+//   - A value may be wrapped in matching single or double quotes, in
+//     which case its interior whitespace and otherwise-special
+//     characters are preserved verbatim.
 //
 package goini
 
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -38,7 +47,21 @@ type DecodeOptionSet map[string]*DecodeOption
 
 // Warning: Prefer to use the public methods since the type of RawSection
 // might change.
-type RawSection map[string][]string
+type RawSection struct {
+	values map[string][]string
+
+	// locations[property][i] is where values[property][i] was set, for
+	// PropertyLocations.
+	locations map[string][]Location
+}
+
+// NewRawSection returns an empty, ready to use RawSection.
+func NewRawSection() RawSection {
+	return RawSection{
+		values:    make(map[string][]string),
+		locations: make(map[string][]Location),
+	}
+}
 
 type RawConfig struct {
 	GlobalSection RawSection
@@ -46,6 +69,10 @@ type RawConfig struct {
 
 	// Ordered based on order in the config file.
 	sectionNames []string
+
+	// sectionLocations[name] is where section name was declared, for
+	// SectionLocation.
+	sectionLocations map[string]Location
 }
 
 // An object for parsing config files and building a RawConfig. Can be
@@ -58,23 +85,43 @@ type RawConfigParser struct {
 	currentLine    string
 	err            error
 	numLines       int
+
+	// filename is recorded against each property/section parsed from
+	// the current file, for Location. Empty when parsing from a reader
+	// with no associated file.
+	filename string
+	// baseDir is the directory relative paths in "!include" directives
+	// are resolved against. Includes are disabled while it is empty.
+	baseDir string
+	// visited holds the absolute paths of already-included files, used
+	// to detect include cycles.
+	visited map[string]bool
+	// includeDepth is the current nesting depth of "!include" directives.
+	includeDepth int
 }
 
 func (s RawSection) addProperty(property, value string) {
-	s[property] = append(s[property], value)
+	s.addPropertyAt(property, value, Location{})
+}
+
+// addPropertyAt is like addProperty, additionally recording where value
+// was set.
+func (s RawSection) addPropertyAt(property, value string, loc Location) {
+	s.values[property] = append(s.values[property], value)
+	s.locations[property] = append(s.locations[property], loc)
 }
 
 // Returns all the values set for a property or the empty list nil if has
 // never been set.
 func (s RawSection) GetPropertyValues(property string) []string {
-	return s[property]
+	return s.values[property]
 }
 
 // If the property has been set at least once, returns all values joined
 // as a space separated string. Returns true if the propery has been set
 // at least once.
 func (s RawSection) GetPropertyNumber(property string) (json.Number, bool) {
-	vs, ok := s[property]
+	vs, ok := s.values[property]
 	if !ok {
 		return "", false
 	}
@@ -84,7 +131,7 @@ func (s RawSection) GetPropertyNumber(property string) (json.Number, bool) {
 // Returns the list of unique properties that have been set at least once.
 func (s RawSection) Properties() []string {
 	keys := []string{}
-	for p := range s {
+	for p := range s.values {
 		keys = append(keys, p)
 	}
 	return keys
@@ -94,29 +141,36 @@ func (dos DecodeOptionSet) Decode(section RawSection, dest interface{}) error {
 	for _, property := range section.Properties() {
 		option, ok := dos[property]
 		if !ok {
-			return fmt.Errorf("unexpected property %s",
-				strconv.Quote(property))
+			return fmt.Errorf("%sunexpected property %s",
+				locationPrefix(section, property, 0), strconv.Quote(property))
 		}
 		values := section.GetPropertyValues(property)
 		if option.Kind == UniqueOption && len(values) != 1 {
-			return fmt.Errorf("property %s cannot be repeated",
-				strconv.Quote(property))
+			return fmt.Errorf("%sproperty %s cannot be repeated",
+				locationPrefix(section, property, 0), strconv.Quote(property))
 		}
-		for _, value := range values {
+		for i, value := range values {
 			if e := option.Parse(value, dest); e != nil {
-				return fmt.Errorf("error parsing %s: %s",
-					strconv.Quote(property), e)
+				return fmt.Errorf("%serror parsing %s: %s",
+					locationPrefix(section, property, i), strconv.Quote(property), e)
 			}
 		}
 	}
 	return nil
 }
 
-// Return the section by name or nil if the section
-// was not defined.
+// Section returns the section named n and true, or the zero RawSection
+// and false if no such section was defined.
 //
-func (c *RawConfig) Section(n string) RawSection {
-	return c.sections[n]
+// BREAKING CHANGE: Section used to return a bare RawSection, nil when n
+// was not defined, since RawSection was a map type. Now that RawSection
+// is a struct (see the Warning on its type) its zero value cannot be
+// compared to nil, so Section reports "not found" via the comma-ok
+// pattern instead, matching GetPropertyNumber. Existing callers that
+// compared the result to nil need to switch to the two-value form.
+func (c *RawConfig) Section(n string) (RawSection, bool) {
+	s, ok := c.sections[n]
+	return s, ok
 }
 
 // Returns the list of unique sections in the config object.
@@ -125,8 +179,57 @@ func (c *RawConfig) Sections() []string {
 }
 
 func NewRawConfigParser() *RawConfigParser {
-	config := &RawConfig{make(RawSection), make(map[string]RawSection), nil}
-	return &RawConfigParser{config, config.GlobalSection, "", nil, 0}
+	config := &RawConfig{NewRawSection(), make(map[string]RawSection), nil, make(map[string]Location)}
+	return &RawConfigParser{config, config.GlobalSection, "", nil, 0, "", "", make(map[string]bool), 0}
+}
+
+// trailingBackslashes returns the number of consecutive '\' characters
+// at the end of s. A line continuation is only a continuation if it ends
+// in an odd number of backslashes -- an even number means the trailing
+// backslashes pair off into escaped literal backslashes instead.
+func trailingBackslashes(s string) int {
+	n := 0
+	for n < len(s) && s[len(s)-1-n] == '\\' {
+		n++
+	}
+	return n
+}
+
+// findUnescaped returns the index of the first occurrence of target in s
+// that is not preceded by an escaping backslash, or -1 if there is none.
+func findUnescaped(s string, target byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			continue
+		}
+		if s[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeSpecial replaces backslash-escaped special characters (\;, \#,
+// \\, \=, \[) with the literal character. Any other backslash is left
+// untouched.
+func unescapeSpecial(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case ';', '#', '\\', '=', '[':
+				b.WriteByte(s[i+1])
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
 }
 
 func (cp *RawConfigParser) parseLine(line string) error {
@@ -134,6 +237,13 @@ func (cp *RawConfigParser) parseLine(line string) error {
 		return cp.err
 	}
 
+	if cp.currentLine == "" {
+		if pattern, ok := includePattern(line); ok {
+			cp.err = cp.include(pattern)
+			return cp.err
+		}
+	}
+
 	if len(line) > 0 && (line[0] == ';' || line[0] == '#') {
 		if cp.currentLine != "" {
 			cp.err = fmt.Errorf("Invalid continuation into comment line on line %d.", cp.numLines)
@@ -142,7 +252,7 @@ func (cp *RawConfigParser) parseLine(line string) error {
 		return nil
 	}
 
-	if len(line) > 0 && line[len(line)-1] == '\\' {
+	if trailingBackslashes(line)%2 == 1 {
 		cp.currentLine += line[:len(line)-1]
 		return nil
 	}
@@ -184,18 +294,33 @@ func (cp *RawConfigParser) parseSectionHeader(line string) error {
 }
 
 func (cp *RawConfigParser) parseProperty(line string) error {
-	parts := strings.SplitN(line, "=", 2)
-	if len(parts) != 2 || len(parts[0]) == 0 {
+	i := findUnescaped(line, '=')
+	if i <= 0 {
 		cp.err = fmt.Errorf("Invalid property on line %d", cp.numLines)
 		return cp.err
 	}
 
-	p := strings.TrimSpace(parts[0])
-	v := strings.TrimSpace(parts[1])
-	cp.currentSection.addProperty(p, v)
+	p := unescapeSpecial(strings.TrimSpace(line[:i]))
+	v := parseValue(line[i+1:])
+	cp.currentSection.addPropertyAt(p, v, Location{cp.filename, cp.numLines})
 	return nil
 }
 
+// parseValue trims and unescapes a raw property value. A value that is
+// entirely wrapped in matching single or double quotes is taken verbatim
+// between the quotes, preserving interior whitespace and the otherwise
+// special '#', ';' and '=' characters.
+func parseValue(raw string) string {
+	v := strings.TrimSpace(raw)
+	if len(v) >= 2 {
+		quote := v[0]
+		if (quote == '"' || quote == '\'') && v[len(v)-1] == quote {
+			return v[1 : len(v)-1]
+		}
+	}
+	return unescapeSpecial(v)
+}
+
 // Returns the new config object or the first error encountered while parsing.
 //
 // Also resets the config parser.
@@ -214,9 +339,10 @@ func (cp *RawConfigParser) addSection(name string) error {
 		return cp.err
 	}
 
-	cp.currentSection = make(map[string][]string)
+	cp.currentSection = NewRawSection()
 	cp.config.sections[name] = cp.currentSection
 	cp.config.sectionNames = append(cp.config.sectionNames, name)
+	cp.config.sectionLocations[name] = Location{cp.filename, cp.numLines}
 
 	return nil
 }
@@ -225,14 +351,51 @@ func (cp *RawConfigParser) Parse(file io.Reader) error {
 	if cp.err != nil {
 		return cp.err
 	}
+	return cp.scan(file)
+}
+
+// ParseWithBaseDir is like Parse, but resolves relative paths in
+// "!include" directives against baseDir instead of disabling them.
+func (cp *RawConfigParser) ParseWithBaseDir(file io.Reader, baseDir string) error {
+	if cp.err != nil {
+		return cp.err
+	}
+	cp.baseDir = baseDir
+	return cp.scan(file)
+}
+
+// scanError wraps a parse error with the line it occurred on. Checking
+// for it lets scan avoid re-wrapping an error that a nested "!include"
+// scan already attributed to one of its own lines.
+type scanError struct {
+	line int
+	err  error
+}
+
+func (e *scanError) Error() string {
+	return fmt.Sprintf("error parsing line %d: %v", e.line, e.err)
+}
+
+func (e *scanError) Unwrap() error {
+	return e.err
+}
+
+// scan reads and parses every line of file, tracking cp.numLines across
+// recursive calls so "!include"d files report their own line numbers.
+func (cp *RawConfigParser) scan(file io.Reader) error {
+	savedNumLines := cp.numLines
+	cp.numLines = 0
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		cp.numLines++
 
 		if err := cp.parseLine(scanner.Text()); err != nil {
-			return fmt.Errorf("error parsing line %d %v",
-				cp.numLines, err)
+			var alreadyLocated *scanError
+			if errors.As(err, &alreadyLocated) {
+				return err
+			}
+			return &scanError{cp.numLines, err}
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -243,6 +406,8 @@ func (cp *RawConfigParser) Parse(file io.Reader) error {
 		return fmt.Errorf(
 			"error parsing line %d: continuation at end of file", cp.numLines)
 	}
+
+	cp.numLines = savedNumLines
 	return nil
 }
 
@@ -255,6 +420,10 @@ func (cp *RawConfigParser) ParseFile(filename string) error {
 	if err != nil {
 		return err
 	}
+	if cp.baseDir == "" {
+		cp.baseDir = filepath.Dir(filename)
+	}
+	cp.filename = filename
 	return cp.Parse(file)
 }
 
@@ -273,3 +442,11 @@ func Parse(reader io.Reader) (*RawConfig, error) {
 	}
 	return cp.Finish()
 }
+
+func ParseWithBaseDir(reader io.Reader, baseDir string) (*RawConfig, error) {
+	cp := NewRawConfigParser()
+	if err := cp.ParseWithBaseDir(reader, baseDir); err != nil {
+		return nil, err
+	}
+	return cp.Finish()
+}
@@ -42,7 +42,7 @@ func joinLines(lines ...string) string {
 func TestSanity(t *testing.T) {
 	c := parseAndFinish(t, "key=value")
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key": {"value"},
 	})
 
@@ -57,7 +57,7 @@ func TestDefaultSection(t *testing.T) {
 		"key2=",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key":  {"value"},
 		"key2": {""},
 	})
@@ -73,7 +73,7 @@ func TestRepeatedKey(t *testing.T) {
 		"key=value2",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key": {"value", "value2"},
 	})
 
@@ -90,7 +90,7 @@ func TestComment(t *testing.T) {
 		"a=; not a comment",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key": {"value # not a comment"},
 		"a":   {"; not a comment"},
 	})
@@ -106,7 +106,7 @@ func TestContinuation(t *testing.T) {
 		"key2=value2",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key": {"value key2=value2"},
 	})
 
@@ -125,7 +125,7 @@ func TestContinuationIntoEmptyLine(t *testing.T) {
 		"",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
 		"key": {""},
 	})
 
@@ -161,18 +161,62 @@ func TestInvalidProperty(t *testing.T) {
 	}
 }
 
+func TestEscapedSpecialCharacters(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		`color=\#fff`,
+		`rule=a\=b\;c`,
+		`path=C:\\\\Program Files`,
+		`literal=\[not a section]`,
+	))
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"color":   {"#fff"},
+		"rule":    {"a=b;c"},
+		"path":    {`C:\\Program Files`},
+		"literal": {"[not a section]"},
+	})
+}
+
+func TestEscapedTrailingBackslashIsNotContinuation(t *testing.T) {
+	c := parseAndFinish(t, `key=value\\`)
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"key": {`value\`},
+	})
+}
+
+func TestEscapedLeadingCommentCharacter(t *testing.T) {
+	c := parseAndFinish(t, `\;key=value`)
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		";key": {"value"},
+	})
+}
+
+func TestQuotedValue(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		`css=".button { color: #fff; margin: 1px; }"`,
+		`single='a # b ; c = d'`,
+	))
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"css":    {".button { color: #fff; margin: 1px; }"},
+		"single": {"a # b ; c = d"},
+	})
+}
+
 func TestLeadingWhitespace(t *testing.T) {
 	c := parseAndFinish(t, "\n\t\t\t[test1]\n\t\t\tquery=select 1\n\t\t\trate=1")
 
-	checkSection(t, "global", c.GlobalSection, RawSection{})
+	checkSection(t, "global", c.GlobalSection, map[string][]string{})
 
 	if len(c.Sections()) > 1 {
 		t.Error("Unexpected sections found: ", c.Sections())
 	}
-	if section := c.Section("test1"); section == nil {
+	if section, ok := c.Section("test1"); !ok {
 		t.Errorf("section not found: got %v", c.Sections())
 	} else {
-		checkSection(t, "test1", section, RawSection{
+		checkSection(t, "test1", section, map[string][]string{
 			"query": []string{"select 1"},
 			"rate":  []string{"1"},
 		})
@@ -184,15 +228,15 @@ func TestSectionEmpty(t *testing.T) {
 		"[section]",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{})
+	checkSection(t, "global", c.GlobalSection, map[string][]string{})
 
 	if len(c.Sections()) > 1 {
 		t.Error("Unexpected sections found: ", c.Sections())
 	}
-	if section := c.Section("section"); section == nil {
+	if section, ok := c.Section("section"); !ok {
 		t.Errorf("section not found: got %v", c.Sections())
 	} else {
-		checkSection(t, "section", section, RawSection{})
+		checkSection(t, "section", section, map[string][]string{})
 	}
 }
 
@@ -202,15 +246,15 @@ func TestSectionPropery(t *testing.T) {
 		"key=value",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{})
+	checkSection(t, "global", c.GlobalSection, map[string][]string{})
 
 	if len(c.Sections()) > 1 {
 		t.Error("Unexpected sections found: ", c.Sections())
 	}
-	if section := c.Section("section"); section == nil {
+	if section, ok := c.Section("section"); !ok {
 		t.Errorf("section not found: got %v", c.Sections())
 	} else {
-		checkSection(t, "section", section, RawSection{"key": {"value"}})
+		checkSection(t, "section", section, map[string][]string{"key": {"value"}})
 	}
 }
 
@@ -221,21 +265,21 @@ func TestMultipleSection(t *testing.T) {
 		"[section2]",
 	))
 
-	checkSection(t, "global", c.GlobalSection, RawSection{})
+	checkSection(t, "global", c.GlobalSection, map[string][]string{})
 
 	if len(c.Sections()) > 2 {
 		t.Error("Unexpected sections found: ", c.Sections())
 	}
 
-	if section := c.Section("section1"); section == nil {
+	if section, ok := c.Section("section1"); !ok {
 		t.Errorf("section not found: got %v", c.Sections())
 	} else {
-		checkSection(t, "section1", section, RawSection{})
+		checkSection(t, "section1", section, map[string][]string{})
 	}
 
-	if section := c.Section("section2"); section == nil {
+	if section, ok := c.Section("section2"); !ok {
 		t.Errorf("section not found: got %v", c.Sections())
 	} else {
-		checkSection(t, "section2", section, RawSection{})
+		checkSection(t, "section2", section, map[string][]string{})
 	}
 }
@@ -0,0 +1,95 @@
+package goini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth caps "!include" nesting to guard against unbounded
+// (or merely very deep) recursion.
+const maxIncludeDepth = 32
+
+// includePattern recognizes a "!include path/glob" or "@include
+// path/glob" directive and returns its glob pattern.
+func includePattern(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range [...]string{"!include ", "@include "} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return strings.TrimSpace(trimmed[len(prefix):]), true
+		}
+	}
+	return "", false
+}
+
+// include expands and parses every file matching pattern into cp's
+// config, resolving a relative pattern against cp.baseDir.
+func (cp *RawConfigParser) include(pattern string) error {
+	if cp.baseDir == "" {
+		return fmt.Errorf(
+			"include directive on line %d requires a base directory; use ParseFile or ParseWithBaseDir",
+			cp.numLines)
+	}
+	if cp.includeDepth >= maxIncludeDepth {
+		return fmt.Errorf("include depth exceeds %d on line %d", maxIncludeDepth, cp.numLines)
+	}
+
+	fullPattern := pattern
+	if !filepath.IsAbs(fullPattern) {
+		fullPattern = filepath.Join(cp.baseDir, fullPattern)
+	}
+
+	matches, err := filepath.Glob(fullPattern)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no files match include pattern %s on line %d",
+			strconv.Quote(pattern), cp.numLines)
+	}
+	sort.Strings(matches)
+
+	for _, match := range matches {
+		if err := cp.includeFile(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *RawConfigParser) includeFile(filename string) error {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	if cp.visited[abs] {
+		return fmt.Errorf("include cycle detected at %s", strconv.Quote(abs))
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+
+	savedBaseDir, savedFilename := cp.baseDir, cp.filename
+	cp.visited[abs] = true
+	cp.baseDir = filepath.Dir(abs)
+	cp.filename = filename
+	cp.includeDepth++
+
+	err = cp.scan(file)
+
+	cp.includeDepth--
+	cp.baseDir = savedBaseDir
+	cp.filename = savedFilename
+	// visited tracks the chain of ancestors currently being included, not
+	// every file ever included, so sibling includes of a shared file
+	// (e.g. two sections both "!include common.ini") aren't mistaken for
+	// a cycle; only an actual ancestor cycle leaves abs set on entry.
+	delete(cp.visited, abs)
+
+	return err
+}
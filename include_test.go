@@ -0,0 +1,130 @@
+package goini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("WriteFile(%s) returned error: %v", path, err)
+	}
+	return path
+}
+
+func TestIncludeDirective(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "included.ini", "value=from_include\n")
+	main := writeTempFile(t, dir, "main.ini", "!include included.ini\n")
+
+	c, err := ParseFile(main)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"value": {"from_include"},
+	})
+}
+
+func TestIncludeGlob(t *testing.T) {
+	dir := t.TempDir()
+	includeDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(includeDir, 0755); err != nil {
+		t.Fatalf("Mkdir() returned error: %v", err)
+	}
+	writeTempFile(t, includeDir, "a.ini", "a=1\n")
+	writeTempFile(t, includeDir, "b.ini", "b=2\n")
+	main := writeTempFile(t, dir, "main.ini", "@include conf.d/*.ini\n")
+
+	c, err := ParseFile(main)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"a": {"1"},
+		"b": {"2"},
+	})
+}
+
+func TestIncludeRelativeToIncludingFile(t *testing.T) {
+	dir := t.TempDir()
+	subdir := filepath.Join(dir, "sub")
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatalf("Mkdir() returned error: %v", err)
+	}
+	writeTempFile(t, subdir, "nested.ini", "value=nested\n")
+	writeTempFile(t, subdir, "middle.ini", "!include nested.ini\n")
+	main := writeTempFile(t, dir, "main.ini", "!include sub/middle.ini\n")
+
+	c, err := ParseFile(main)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"value": {"nested"},
+	})
+}
+
+func TestIncludeDiamondSharedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "common.ini", "shared=1\n")
+	writeTempFile(t, dir, "b.ini", "!include common.ini\nb=1\n")
+	writeTempFile(t, dir, "d.ini", "!include common.ini\nd=1\n")
+	main := writeTempFile(t, dir, "main.ini", "!include b.ini\n!include d.ini\n")
+
+	c, err := ParseFile(main)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	checkSection(t, "global", c.GlobalSection, map[string][]string{
+		"shared": {"1", "1"},
+		"b":      {"1"},
+		"d":      {"1"},
+	})
+}
+
+func TestIncludeCycleDetected(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTempFile(t, dir, "a.ini", "!include b.ini\n")
+	writeTempFile(t, dir, "b.ini", "!include a.ini\n")
+
+	if _, err := ParseFile(a); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("ParseFile() = %v, want include cycle error", err)
+	}
+}
+
+func TestIncludeNestedErrorNotDoubled(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "bad.ini", "not a valid line\n")
+	main := writeTempFile(t, dir, "main.ini", "!include bad.ini\n")
+
+	_, err := ParseFile(main)
+	if err == nil {
+		t.Fatalf("ParseFile() succeeded, want error")
+	}
+	if n := strings.Count(err.Error(), "error parsing line"); n != 1 {
+		t.Errorf("ParseFile() error = %q, want exactly one \"error parsing line\" prefix, got %d", err, n)
+	}
+}
+
+func TestIncludeWithoutBaseDirDisabled(t *testing.T) {
+	if _, err := Parse(strings.NewReader("!include foo.ini\n")); err == nil {
+		t.Errorf("Parse() with include directive succeeded, want error")
+	}
+}
+
+func TestIncludeNoMatches(t *testing.T) {
+	dir := t.TempDir()
+	main := writeTempFile(t, dir, "main.ini", "!include missing-*.ini\n")
+
+	if _, err := ParseFile(main); err == nil {
+		t.Errorf("ParseFile() with no matching includes succeeded, want error")
+	}
+}
@@ -0,0 +1,40 @@
+package goini
+
+import "fmt"
+
+// A Location identifies where in a source file a section or property was
+// declared.
+type Location struct {
+	// Filename is empty if the value did not come from a named file,
+	// e.g. it was set via AddOption/SetOption or parsed from a reader
+	// passed directly to Parse.
+	Filename string
+	Line     int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.Filename, l.Line)
+}
+
+// PropertyLocations returns the locations of every value set for
+// property, in the same order as GetPropertyValues. It returns nil if
+// the property has never been set.
+func (s RawSection) PropertyLocations(property string) []Location {
+	return s.locations[property]
+}
+
+// SectionLocation returns where section name was declared, or the zero
+// Location if it was not defined.
+func (c *RawConfig) SectionLocation(name string) Location {
+	return c.sectionLocations[name]
+}
+
+// locationPrefix returns "filename:line: " for the i'th value set for
+// property in section, or "" if that location is unknown.
+func locationPrefix(section RawSection, property string, i int) string {
+	locs := section.PropertyLocations(property)
+	if i >= len(locs) || locs[i].Filename == "" {
+		return ""
+	}
+	return locs[i].String() + ": "
+}
@@ -0,0 +1,126 @@
+package goini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocationString(t *testing.T) {
+	loc := Location{Filename: "app.ini", Line: 3}
+	if got, want := loc.String(), "app.ini:3"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestPropertyLocations(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "app.ini", joinLines(
+		"key=value",
+		"key=value2",
+	))
+
+	c, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	locs := c.GlobalSection.PropertyLocations("key")
+	want := []Location{{path, 1}, {path, 2}}
+	for i, loc := range want {
+		if i >= len(locs) || locs[i] != loc {
+			t.Errorf("PropertyLocations(\"key\") = %v, want %v", locs, want)
+			break
+		}
+	}
+
+	if got := c.GlobalSection.PropertyLocations("missing"); got != nil {
+		t.Errorf("PropertyLocations(\"missing\") = %v, want nil", got)
+	}
+}
+
+func TestSectionLocation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "app.ini", joinLines(
+		"",
+		"[section]",
+		"key=value",
+	))
+
+	c, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	if got, want := c.SectionLocation("section"), (Location{path, 2}); got != want {
+		t.Errorf("SectionLocation(\"section\") = %v, want %v", got, want)
+	}
+	if got, want := c.SectionLocation("missing"), (Location{}); got != want {
+		t.Errorf("SectionLocation(\"missing\") = %v, want %v", got, want)
+	}
+}
+
+func TestLocationOmittedWithoutFile(t *testing.T) {
+	c := parseAndFinish(t, "key=value")
+
+	if locs := c.GlobalSection.PropertyLocations("key"); len(locs) != 1 || locs[0].Filename != "" {
+		t.Errorf("PropertyLocations(\"key\") = %v, want a single location with no filename", locs)
+	}
+}
+
+func TestDecodeErrorIncludesLocation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "app.ini", "key=1\nkey=2\n")
+
+	c, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	spec := DecodeOptionSet{
+		"key": &DecodeOption{Kind: UniqueOption, Usage: "A help message."},
+	}
+	var dest int
+	err = spec.Decode(c.GlobalSection, &dest)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":1: ") {
+		t.Errorf("Decode() = %v, want error prefixed with %q", err, path+":1: ")
+	}
+}
+
+func TestResolvePreservesLocation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "app.ini", "path=%(root)s/logs\n")
+
+	c, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+	c.AddOption("", "root", "/var/app")
+
+	resolved, err := c.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	locs := resolved.GlobalSection.PropertyLocations("path")
+	if len(locs) != 1 || locs[0] != (Location{path, 1}) {
+		t.Errorf("PropertyLocations(\"path\") = %v, want [%v]", locs, Location{path, 1})
+	}
+}
+
+func TestUnmarshalErrorIncludesLocation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "app.ini", "count=not-a-number\n")
+
+	c, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() returned error: %v", err)
+	}
+
+	var dest struct {
+		Count int `ini:"count"`
+	}
+	err = Unmarshal(c.GlobalSection, &dest)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":1: ") {
+		t.Errorf("Unmarshal() = %v, want error prefixed with %q", err, path+":1: ")
+	}
+}
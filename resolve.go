@@ -0,0 +1,192 @@
+package goini
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxInterpolationDepth is the interpolation depth used by
+// RawConfig.Resolve.
+const DefaultMaxInterpolationDepth = 200
+
+// interpolationPattern matches a "%(name)s" reference.
+var interpolationPattern = regexp.MustCompile(`%\(([^)]*)\)s`)
+
+// A Config is a RawConfig with all "%(name)s" references in its property
+// values expanded. See RawConfig.Resolve.
+type Config struct {
+	GlobalSection RawSection
+	sections      map[string]RawSection
+
+	// Ordered based on order in the config file.
+	sectionNames []string
+}
+
+// Section returns the resolved section named n and true, or the zero
+// RawSection and false if no such section was defined.
+//
+// BREAKING CHANGE: like RawConfig.Section, this used to return a bare
+// RawSection, nil when n was not defined; it now uses the comma-ok
+// pattern since RawSection's zero value is a non-nilable struct.
+func (c *Config) Section(n string) (RawSection, bool) {
+	s, ok := c.sections[n]
+	return s, ok
+}
+
+// Returns the list of unique sections in the config object.
+func (c *Config) Sections() []string {
+	return c.sectionNames
+}
+
+// section returns the RawSection for name, creating an empty one (and
+// registering it in sectionNames) if it does not already exist. The
+// empty string refers to the global section.
+func (c *RawConfig) section(name string) RawSection {
+	if name == "" {
+		return c.GlobalSection
+	}
+	s, ok := c.sections[name]
+	if !ok {
+		s = NewRawSection()
+		c.sections[name] = s
+		c.sectionNames = append(c.sectionNames, name)
+	}
+	return s
+}
+
+// AddOption appends value to the (possibly repeated) option key in
+// section, creating section if it does not already exist. Pass "" for
+// section to set a global option. This is typically used to inject
+// defaults before calling Resolve.
+func (c *RawConfig) AddOption(section, key, value string) {
+	c.section(section).addProperty(key, value)
+}
+
+// SetOption sets key in section to the single value value, discarding
+// any previous values, creating section if it does not already exist.
+// Pass "" for section to set a global option.
+func (c *RawConfig) SetOption(section, key, value string) {
+	c.section(section).SetProperty(key, value)
+}
+
+// Resolve expands all "%(name)s" references in c's property values,
+// looking up name first in the section the reference occurs in and then
+// in the global section, recursively up to DefaultMaxInterpolationDepth
+// levels deep. It returns an error naming the offending keys if a
+// reference cannot be resolved or a cycle is detected.
+func (c *RawConfig) Resolve() (*Config, error) {
+	return c.ResolveWithMaxDepth(DefaultMaxInterpolationDepth)
+}
+
+// ResolveWithMaxDepth is like Resolve but allows overriding the maximum
+// interpolation depth.
+func (c *RawConfig) ResolveWithMaxDepth(maxDepth int) (*Config, error) {
+	resolved := &Config{
+		sections:     make(map[string]RawSection),
+		sectionNames: c.sectionNames,
+	}
+
+	global, err := c.resolveSection("", c.GlobalSection, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	resolved.GlobalSection = global
+
+	for _, name := range c.sectionNames {
+		section, err := c.resolveSection(name, c.sections[name], maxDepth)
+		if err != nil {
+			return nil, err
+		}
+		resolved.sections[name] = section
+	}
+
+	return resolved, nil
+}
+
+func (c *RawConfig) resolveSection(sectionName string, section RawSection, maxDepth int) (RawSection, error) {
+	out := NewRawSection()
+	for _, key := range section.Properties() {
+		locs := section.PropertyLocations(key)
+		for i, value := range section.GetPropertyValues(key) {
+			resolvedValue, err := c.resolveValue(sectionName, value, maxDepth,
+				[]string{qualifiedName(sectionName, key)})
+			if err != nil {
+				return RawSection{}, err
+			}
+			out.addPropertyAt(key, resolvedValue, locs[i])
+		}
+	}
+	return out, nil
+}
+
+// resolveValue expands the "%(name)s" references in value. path records
+// the chain of qualified names ("section.key") visited so far, used both
+// for cycle detection and for reporting where an error occurred.
+func (c *RawConfig) resolveValue(sectionName, value string, depthRemaining int, path []string) (string, error) {
+	var resolveErr error
+	result := interpolationPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+
+		name := interpolationPattern.FindStringSubmatch(match)[1]
+		ref := qualifiedName(sectionName, name)
+
+		for _, seen := range path {
+			if seen == ref {
+				resolveErr = fmt.Errorf("interpolation cycle detected: %s",
+					strings.Join(append(path, ref), " -> "))
+				return match
+			}
+		}
+		if depthRemaining <= 0 {
+			resolveErr = fmt.Errorf("max interpolation depth exceeded resolving %s",
+				strings.Join(append(path, ref), " -> "))
+			return match
+		}
+
+		refValue, ok := c.lookupOption(sectionName, name)
+		if !ok {
+			resolveErr = fmt.Errorf("undefined option %s referenced by %s",
+				strconv.Quote(name), strings.Join(path, " -> "))
+			return match
+		}
+
+		nextPath := append(append([]string{}, path...), ref)
+		resolvedValue, err := c.resolveValue(sectionName, refValue, depthRemaining-1, nextPath)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return resolvedValue
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
+
+// lookupOption looks up name first in sectionName and then in the global
+// section, returning its last set value.
+func (c *RawConfig) lookupOption(sectionName, name string) (string, bool) {
+	if sectionName != "" {
+		if section, ok := c.sections[sectionName]; ok {
+			if vs := section.GetPropertyValues(name); len(vs) > 0 {
+				return vs[len(vs)-1], true
+			}
+		}
+	}
+	if vs := c.GlobalSection.GetPropertyValues(name); len(vs) > 0 {
+		return vs[len(vs)-1], true
+	}
+	return "", false
+}
+
+func qualifiedName(sectionName, key string) string {
+	if sectionName == "" {
+		return key
+	}
+	return sectionName + "." + key
+}
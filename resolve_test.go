@@ -0,0 +1,78 @@
+package goini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveGlobalReference(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"root=/var/app",
+		"path=%(root)s/logs",
+	))
+
+	resolved, err := c.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	if got, want := resolved.GlobalSection.GetPropertyValues("path"), []string{"/var/app/logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path = %v, want %v", got, want)
+	}
+}
+
+func TestResolveSectionThenGlobalFallback(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"root=/var/app",
+		"[section]",
+		"root=/var/section",
+		"path=%(root)s/logs",
+	))
+
+	resolved, err := c.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	section, ok := resolved.Section("section")
+	if !ok {
+		t.Fatalf("section not found: got %v", resolved.Sections())
+	}
+	if got, want := section.GetPropertyValues("path"), []string{"/var/section/logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path = %v, want %v", got, want)
+	}
+}
+
+func TestResolveAddOptionBeforeResolve(t *testing.T) {
+	c := parseAndFinish(t, "path=%(root)s/logs")
+	c.AddOption("", "root", "/var/app")
+
+	resolved, err := c.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	if got, want := resolved.GlobalSection.GetPropertyValues("path"), []string{"/var/app/logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path = %v, want %v", got, want)
+	}
+}
+
+func TestResolveCycleDetection(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"a=%(b)s",
+		"b=%(a)s",
+	))
+
+	if _, err := c.Resolve(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Resolve() = %v, want cycle error", err)
+	}
+}
+
+func TestResolveUndefinedReference(t *testing.T) {
+	c := parseAndFinish(t, "path=%(root)s/logs")
+
+	if _, err := c.Resolve(); err == nil {
+		t.Errorf("Resolve() with undefined reference succeeded, want error")
+	}
+}
@@ -0,0 +1,248 @@
+package goini
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal binds the properties in section to the fields of the struct
+// pointed to by dest using `ini:"name,unique|multi,required"` struct
+// tags, eliminating the boilerplate of a hand-written DecodeOptionSet.
+//
+// The tag's name selects the property to bind; "unique" (the default for
+// non-slice fields) requires the property be set at most once, "multi"
+// (the default for slice fields) allows it to repeat, and "required"
+// fails the decode if the property is never set. Fields without an
+// "ini" tag, or tagged "ini:\"-\"", are ignored.
+//
+// Supported field types are string, the int/uint/float families, bool,
+// time.Duration, []T of any supported T, and any type implementing
+// encoding.TextUnmarshaler. Bool values are parsed case-insensitively as
+// t/true/y/yes/on/1 or f/false/n/no/off/0.
+//
+// It is an error for section to contain a property that is not bound to
+// any field.
+func Unmarshal(section RawSection, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Unmarshal destination must be a pointer to a struct, got %T", dest)
+	}
+
+	structVal := v.Elem()
+	structType := structVal.Type()
+	consumed := make(map[string]bool)
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		spec, ok := parseIniTag(field)
+		if !ok {
+			continue
+		}
+		if spec.multi && field.Type.Kind() != reflect.Slice {
+			return fmt.Errorf("field %s tagged \"multi\" must be a slice, got %s",
+				field.Name, field.Type)
+		}
+		consumed[spec.name] = true
+
+		values := section.GetPropertyValues(spec.name)
+		if len(values) == 0 {
+			if spec.required {
+				return fmt.Errorf("missing required property %s", strconv.Quote(spec.name))
+			}
+			continue
+		}
+		if !spec.multi && len(values) != 1 {
+			return fmt.Errorf("%sproperty %s cannot be repeated",
+				locationPrefix(section, spec.name, 0), strconv.Quote(spec.name))
+		}
+
+		fieldVal := structVal.Field(i)
+		if spec.multi {
+			slice := reflect.MakeSlice(fieldVal.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := setScalar(slice.Index(j), value); err != nil {
+					return fmt.Errorf("%serror parsing %s: %s",
+						locationPrefix(section, spec.name, j), strconv.Quote(spec.name), err)
+				}
+			}
+			fieldVal.Set(slice)
+		} else if err := setScalar(fieldVal, values[0]); err != nil {
+			return fmt.Errorf("%serror parsing %s: %s",
+				locationPrefix(section, spec.name, 0), strconv.Quote(spec.name), err)
+		}
+	}
+
+	for _, property := range section.Properties() {
+		if !consumed[property] {
+			return fmt.Errorf("%sunexpected property %s",
+				locationPrefix(section, property, 0), strconv.Quote(property))
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalSections binds every section in c to dest, which must be a
+// pointer to either a map[string]T or a []T (T or *T, where T is a
+// struct understood by Unmarshal). Map keys are section names; slice
+// elements follow c.Sections() order.
+func (c *RawConfig) UnmarshalSections(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr {
+		return fmt.Errorf("UnmarshalSections destination must be a pointer to a map or slice, got %T", dest)
+	}
+
+	switch elem := v.Elem(); elem.Kind() {
+	case reflect.Map:
+		if elem.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("UnmarshalSections map destination must be keyed by string, got %s", elem.Type())
+		}
+		if elem.IsNil() {
+			elem.Set(reflect.MakeMap(elem.Type()))
+		}
+		for _, name := range c.sectionNames {
+			decoded, err := decodeSection(elem.Type().Elem(), c.sections[name])
+			if err != nil {
+				return fmt.Errorf("section %s: %s", strconv.Quote(name), err)
+			}
+			elem.SetMapIndex(reflect.ValueOf(name), decoded)
+		}
+		return nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(elem.Type(), 0, len(c.sectionNames))
+		for _, name := range c.sectionNames {
+			decoded, err := decodeSection(elem.Type().Elem(), c.sections[name])
+			if err != nil {
+				return fmt.Errorf("section %s: %s", strconv.Quote(name), err)
+			}
+			slice = reflect.Append(slice, decoded)
+		}
+		elem.Set(slice)
+		return nil
+	default:
+		return fmt.Errorf("UnmarshalSections destination must be a pointer to a map or slice, got %T", dest)
+	}
+}
+
+// decodeSection unmarshals section into a freshly allocated value of
+// elemType, which must be a struct type or a pointer to one.
+func decodeSection(elemType reflect.Type, section RawSection) (reflect.Value, error) {
+	isPtr := elemType.Kind() == reflect.Ptr
+	structType := elemType
+	if isPtr {
+		structType = elemType.Elem()
+	}
+
+	target := reflect.New(structType)
+	if err := Unmarshal(section, target.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if isPtr {
+		return target, nil
+	}
+	return target.Elem(), nil
+}
+
+type iniFieldSpec struct {
+	name     string
+	multi    bool
+	required bool
+}
+
+func parseIniTag(field reflect.StructField) (iniFieldSpec, bool) {
+	tag, ok := field.Tag.Lookup("ini")
+	if !ok || tag == "-" {
+		return iniFieldSpec{}, false
+	}
+
+	parts := strings.Split(tag, ",")
+	spec := iniFieldSpec{
+		name:  parts[0],
+		multi: field.Type.Kind() == reflect.Slice,
+	}
+	if spec.name == "" {
+		spec.name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "unique":
+			spec.multi = false
+		case "multi":
+			spec.multi = true
+		case "required":
+			spec.required = true
+		}
+	}
+	return spec, true
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setScalar parses value into v, which must be settable and addressable.
+func setScalar(v reflect.Value, value string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+		return nil
+	case v.Kind() == reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr:
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+		return nil
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+}
+
+// parseBool parses s as a boolean, accepting t/true/y/yes/on/1 and
+// f/false/n/no/off/0 case-insensitively.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "t", "true", "y", "yes", "on", "1":
+		return true, nil
+	case "f", "false", "n", "no", "off", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean value %s", strconv.Quote(s))
+	}
+}
@@ -0,0 +1,115 @@
+package goini
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type unmarshalTarget struct {
+	Name     string        `ini:"name,required"`
+	Rate     int           `ini:"rate"`
+	Ratio    float64       `ini:"ratio"`
+	Enabled  bool          `ini:"enabled"`
+	Timeout  time.Duration `ini:"timeout"`
+	Tags     []string      `ini:"tag"`
+	Internal string
+}
+
+func TestUnmarshal(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"name=db",
+		"rate=5",
+		"ratio=0.5",
+		"enabled=yes",
+		"timeout=2s",
+		"tag=a",
+		"tag=b",
+	))
+
+	var got unmarshalTarget
+	if err := Unmarshal(c.GlobalSection, &got); err != nil {
+		t.Fatalf("Unmarshal() returned error: %v", err)
+	}
+
+	want := unmarshalTarget{
+		Name:    "db",
+		Rate:    5,
+		Ratio:   0.5,
+		Enabled: true,
+		Timeout: 2 * time.Second,
+		Tags:    []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalMissingRequired(t *testing.T) {
+	c := parseAndFinish(t, "rate=5")
+
+	var got unmarshalTarget
+	if err := Unmarshal(c.GlobalSection, &got); err == nil {
+		t.Errorf("Unmarshal() with missing required property succeeded, want error")
+	}
+}
+
+func TestUnmarshalUnexpectedProperty(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"name=db",
+		"bogus=1",
+	))
+
+	var got unmarshalTarget
+	if err := Unmarshal(c.GlobalSection, &got); err == nil {
+		t.Errorf("Unmarshal() with unexpected property succeeded, want error")
+	}
+}
+
+func TestUnmarshalMultiOnNonSliceField(t *testing.T) {
+	type badTarget struct {
+		Rate int `ini:"rate,multi"`
+	}
+	c := parseAndFinish(t, "rate=5")
+
+	var got badTarget
+	if err := Unmarshal(c.GlobalSection, &got); err == nil {
+		t.Errorf("Unmarshal() with \"multi\" on a non-slice field succeeded, want error")
+	}
+}
+
+func TestUnmarshalSectionsMap(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"[db1]",
+		"name=db1",
+		"[db2]",
+		"name=db2",
+	))
+
+	got := map[string]unmarshalTarget{}
+	if err := c.UnmarshalSections(&got); err != nil {
+		t.Fatalf("UnmarshalSections() returned error: %v", err)
+	}
+
+	if got["db1"].Name != "db1" || got["db2"].Name != "db2" {
+		t.Errorf("UnmarshalSections() = %+v", got)
+	}
+}
+
+func TestUnmarshalSectionsSlice(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"[db1]",
+		"name=db1",
+		"[db2]",
+		"name=db2",
+	))
+
+	var got []unmarshalTarget
+	if err := c.UnmarshalSections(&got); err != nil {
+		t.Fatalf("UnmarshalSections() returned error: %v", err)
+	}
+
+	if len(got) != 2 || got[0].Name != "db1" || got[1].Name != "db2" {
+		t.Errorf("UnmarshalSections() = %+v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package goini
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SetProperty sets name to the single value value, discarding any
+// previous values for name.
+func (s RawSection) SetProperty(name, value string) {
+	s.values[name] = []string{value}
+	s.locations[name] = []Location{{}}
+}
+
+// DeleteProperty removes all values set for name. It is a no-op if name
+// was never set.
+func (s RawSection) DeleteProperty(name string) {
+	delete(s.values, name)
+	delete(s.locations, name)
+}
+
+// AddSection adds and returns a new, empty section named name, or
+// returns an error if a section with that name already exists.
+func (c *RawConfig) AddSection(name string) (RawSection, error) {
+	if _, ok := c.sections[name]; ok {
+		return RawSection{}, fmt.Errorf("section %s already exists", strconv.Quote(name))
+	}
+	return c.section(name), nil
+}
+
+// DeleteSection removes the section named name. It is a no-op if no such
+// section exists.
+func (c *RawConfig) DeleteSection(name string) {
+	if _, ok := c.sections[name]; !ok {
+		return
+	}
+	delete(c.sections, name)
+	for i, n := range c.sectionNames {
+		if n == name {
+			c.sectionNames = append(c.sectionNames[:i], c.sectionNames[i+1:]...)
+			break
+		}
+	}
+}
+
+// Marshal serializes c as a .ini file, preserving section order and
+// repeated keys. It returns an error if any property value contains an
+// embedded newline, which this dialect has no way to round-trip: a
+// continuation line joins onto the next without reinserting the
+// newline, so writing one out would silently corrupt it on reparse.
+func Marshal(c *RawConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeSection(&buf, c.GlobalSection); err != nil {
+		return nil, err
+	}
+	for _, name := range c.sectionNames {
+		fmt.Fprintf(&buf, "[%s]\n", name)
+		if err := writeSection(&buf, c.sections[name]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes c to w as a .ini file. It implements io.WriterTo.
+func (c *RawConfig) WriteTo(w io.Writer) (int64, error) {
+	data, err := Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func writeSection(buf *bytes.Buffer, section RawSection) error {
+	properties := section.Properties()
+	sort.Strings(properties)
+	for _, key := range properties {
+		for _, value := range section.GetPropertyValues(key) {
+			buf.WriteString(escapeSpecial(key))
+			buf.WriteByte('=')
+			if err := writeValue(buf, value); err != nil {
+				return fmt.Errorf("property %s: %s", strconv.Quote(key), err)
+			}
+			buf.WriteByte('\n')
+		}
+	}
+	return nil
+}
+
+// writeValue writes a single property value, escaping special
+// characters and quoting if necessary to preserve leading/trailing
+// whitespace or to stop a value that merely looks quoted (starts and
+// ends with the same quote character) from being parsed as if it were.
+// It returns an error if value contains an embedded newline, since this
+// dialect cannot represent one.
+func writeValue(buf *bytes.Buffer, value string) error {
+	if strings.Contains(value, "\n") {
+		return fmt.Errorf("value %s contains an embedded newline, which this dialect cannot represent",
+			strconv.Quote(value))
+	}
+
+	if strings.TrimSpace(value) != value || looksQuoted(value) {
+		buf.WriteString(quoteValue(value))
+		return nil
+	}
+
+	buf.WriteString(escapeSpecial(value))
+	return nil
+}
+
+// looksQuoted reports whether value starts and ends with the same quote
+// character, which is exactly what parseValue treats as an already
+// quoted value.
+func looksQuoted(value string) bool {
+	if len(value) < 2 {
+		return false
+	}
+	quote := value[0]
+	return (quote == '"' || quote == '\'') && value[len(value)-1] == quote
+}
+
+// escapeSpecial is the inverse of unescapeSpecial: it backslash-escapes
+// every occurrence of a character that unescapeSpecial treats as
+// special.
+func escapeSpecial(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', ';', '#', '=', '[':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// quoteValue wraps value in whichever of '"' or '\'' does not appear in
+// value, defaulting to '"'.
+func quoteValue(value string) string {
+	quote := byte('"')
+	if strings.IndexByte(value, '"') >= 0 && strings.IndexByte(value, '\'') < 0 {
+		quote = '\''
+	}
+	return string(quote) + value + string(quote)
+}
@@ -0,0 +1,117 @@
+package goini
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func roundTrip(t *testing.T, c *RawConfig) *RawConfig {
+	data, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+	reparsed, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse(Marshal(c)) returned error: %v\noutput:\n%s", err, data)
+	}
+	return reparsed
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	c := parseAndFinish(t, joinLines(
+		"key=value",
+		"key=value2",
+		"[section]",
+		"nested=1",
+	))
+
+	reparsed := roundTrip(t, c)
+
+	checkSection(t, "global", reparsed.GlobalSection, map[string][]string{
+		"key": {"value", "value2"},
+	})
+	if section, ok := reparsed.Section("section"); !ok {
+		t.Fatalf("section not found: got %v", reparsed.Sections())
+	} else {
+		checkSection(t, "section", section, map[string][]string{"nested": {"1"}})
+	}
+}
+
+func TestWriteEscapesSpecialCharacters(t *testing.T) {
+	c := NewRawConfigParser().config
+	c.AddOption("", "color", "#fff")
+	c.AddOption("", "rule", "a=b;c")
+
+	reparsed := roundTrip(t, c)
+
+	checkSection(t, "global", reparsed.GlobalSection, map[string][]string{
+		"color": {"#fff"},
+		"rule":  {"a=b;c"},
+	})
+}
+
+func TestWriteQuotesWhitespace(t *testing.T) {
+	c := NewRawConfigParser().config
+	c.AddOption("", "padded", "  spaced  ")
+
+	data, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `"  spaced  "`) {
+		t.Errorf("Marshal() = %q, want quoted value", data)
+	}
+
+	reparsed := roundTrip(t, c)
+	checkSection(t, "global", reparsed.GlobalSection, map[string][]string{
+		"padded": {"  spaced  "},
+	})
+}
+
+func TestWriteQuoteLookingValue(t *testing.T) {
+	c := NewRawConfigParser().config
+	c.AddOption("", "single", "'hello'")
+	c.AddOption("", "double", `"hello"`)
+
+	reparsed := roundTrip(t, c)
+	checkSection(t, "global", reparsed.GlobalSection, map[string][]string{
+		"single": {"'hello'"},
+		"double": {`"hello"`},
+	})
+}
+
+func TestWriteEmbeddedNewlineRejected(t *testing.T) {
+	c := NewRawConfigParser().config
+	c.AddOption("", "multi", "line1\nline2")
+
+	if _, err := Marshal(c); err == nil {
+		t.Errorf("Marshal() with embedded newline succeeded, want error")
+	}
+}
+
+func TestMutators(t *testing.T) {
+	c := NewRawConfigParser().config
+	section, err := c.AddSection("section")
+	if err != nil {
+		t.Fatalf("AddSection() returned error: %v", err)
+	}
+	section.SetProperty("key", "value")
+	if got := section.GetPropertyValues("key"); len(got) != 1 || got[0] != "value" {
+		t.Errorf("GetPropertyValues(\"key\") = %v, want [value]", got)
+	}
+
+	section.DeleteProperty("key")
+	if got := section.GetPropertyValues("key"); got != nil {
+		t.Errorf("GetPropertyValues(\"key\") = %v, want nil after delete", got)
+	}
+
+	if _, err := c.AddSection("section"); err == nil {
+		t.Errorf("AddSection() of duplicate section succeeded, want error")
+	}
+
+	c.DeleteSection("section")
+	if _, ok := c.Section("section"); ok {
+		t.Errorf("section still present after DeleteSection")
+	}
+}